@@ -0,0 +1,101 @@
+package machineid
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// EtcdClient is the minimal subset of an etcd v3 client that EtcdAllocator
+// needs to lease a machine ID bound to an etcd lease.
+type EtcdClient interface {
+	// Grant creates a lease that lives for ttlSeconds unless renewed by
+	// KeepAlive, returning the lease ID.
+	Grant(ctx context.Context, ttlSeconds int64) (leaseID int64, err error)
+	// KeepAlive starts renewing leaseID in the background until ctx is
+	// canceled or the client is closed.
+	KeepAlive(ctx context.Context, leaseID int64) error
+	// PutIfAbsent atomically creates key with value, attached to leaseID,
+	// only if key does not already exist, reporting whether it did so.
+	PutIfAbsent(ctx context.Context, key, value string, leaseID int64) (ok bool, err error)
+	// Revoke releases leaseID, deleting every key attached to it.
+	Revoke(ctx context.Context, leaseID int64) error
+	// TimeToLive returns leaseID's remaining TTL in seconds, or -1 if the
+	// lease has already expired or been revoked.
+	TimeToLive(ctx context.Context, leaseID int64) (ttlSeconds int64, err error)
+}
+
+// etcdKeyPrefix is the namespace under which EtcdAllocator claims machine
+// IDs, one key per ID: /snowflake/machines/<id>.
+const etcdKeyPrefix = "/snowflake/machines/"
+
+// EtcdAllocator leases a machine ID by attempting a compare-and-swap put on
+// /snowflake/machines/<id> for successive candidate IDs, bound to an etcd
+// lease, until one succeeds. The lease's TTL bounds how long a crashed
+// instance can hold an ID it never releases.
+type EtcdAllocator struct {
+	client  EtcdClient
+	leaseID int64
+	id      uint16
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewEtcdAllocator grants a lease and claims the lowest unclaimed machine
+// ID in [0, maxID].
+func NewEtcdAllocator(client EtcdClient, maxID uint16, ttlSeconds int64) (*EtcdAllocator, error) {
+	leaseID, err := client.Grant(context.Background(), ttlSeconds)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.KeepAlive(context.Background(), leaseID); err != nil {
+		return nil, err
+	}
+
+	for id := uint16(0); ; id++ {
+		key := etcdKeyPrefix + strconv.Itoa(int(id))
+		ok, err := client.PutIfAbsent(context.Background(), key, "", leaseID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &EtcdAllocator{client: client, leaseID: leaseID, id: id}, nil
+		}
+		if id == maxID {
+			return nil, ErrNoAvailableMachineID
+		}
+	}
+}
+
+// MachineID returns the leased machine ID. It is suitable for use as
+// Settings.MachineID.
+func (a *EtcdAllocator) MachineID() (uint16, error) {
+	return a.id, nil
+}
+
+// CheckMachineID reports whether id is the machine ID currently leased by
+// a, querying etcd for the lease's remaining TTL so a lease lost to
+// expiry or revocation is actually detected rather than trusting the
+// in-memory id. It is suitable for use as Settings.CheckMachineID.
+func (a *EtcdAllocator) CheckMachineID(id uint16) bool {
+	if id != a.id {
+		return false
+	}
+
+	ttl, err := a.client.TimeToLive(context.Background(), a.leaseID)
+	return err == nil && ttl > 0
+}
+
+// Close revokes the lease, releasing the claimed key immediately. Calling
+// Close more than once is a no-op after the first call.
+func (a *EtcdAllocator) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	return a.client.Revoke(context.Background(), a.leaseID)
+}
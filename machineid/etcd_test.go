@@ -0,0 +1,76 @@
+package machineid
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestEtcdAllocatorClaimsLowestUnclaimedID(t *testing.T) {
+	client := newFakeEtcdClient()
+	// Pre-occupy ids 0 and 1 under a different lease.
+	client.keys[etcdKeyPrefix+"0"] = 999
+	client.keys[etcdKeyPrefix+"1"] = 999
+
+	a, err := NewEtcdAllocator(client, 10, 30)
+	if err != nil {
+		t.Fatalf("NewEtcdAllocator: %v", err)
+	}
+	defer a.Close()
+
+	id, _ := a.MachineID()
+	if id != 2 {
+		t.Errorf("MachineID = %d, want 2 (lowest unclaimed)", id)
+	}
+}
+
+func TestEtcdAllocatorNoAvailableMachineID(t *testing.T) {
+	client := newFakeEtcdClient()
+	for i := uint16(0); i <= 2; i++ {
+		client.keys[etcdKeyPrefix+strconv.Itoa(int(i))] = 999
+	}
+
+	_, err := NewEtcdAllocator(client, 2, 30)
+	if err != ErrNoAvailableMachineID {
+		t.Errorf("NewEtcdAllocator: got %v, want ErrNoAvailableMachineID", err)
+	}
+}
+
+func TestEtcdAllocatorCheckMachineID(t *testing.T) {
+	client := newFakeEtcdClient()
+	a, err := NewEtcdAllocator(client, 10, 30)
+	if err != nil {
+		t.Fatalf("NewEtcdAllocator: %v", err)
+	}
+	defer a.Close()
+
+	id, _ := a.MachineID()
+	if !a.CheckMachineID(id) {
+		t.Error("CheckMachineID = false while lease is held, want true")
+	}
+	if a.CheckMachineID(id + 1) {
+		t.Error("CheckMachineID for a different id = true, want false")
+	}
+
+	if err := client.Revoke(context.Background(), a.leaseID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if a.CheckMachineID(id) {
+		t.Error("CheckMachineID after lease revoked = true, want false")
+	}
+}
+
+func TestEtcdAllocatorCloseIsIdempotent(t *testing.T) {
+	client := newFakeEtcdClient()
+	a, err := NewEtcdAllocator(client, 10, 30)
+	if err != nil {
+		t.Fatalf("NewEtcdAllocator: %v", err)
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package machineid
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errNotFound is returned by fakeRedisClient.Get for a key that was never
+// set or has since been deleted, standing in for redis.Nil.
+var errNotFound = errors.New("fakeRedisClient: key not found")
+
+// fakeRedisClient is an in-memory RedisClient used to exercise
+// RedisAllocator without a real Redis instance.
+type fakeRedisClient struct {
+	mu        sync.Mutex
+	counters  map[string]int64
+	deleted   map[string]bool
+	expireCnt map[string]int
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{
+		counters:  make(map[string]int64),
+		deleted:   make(map[string]bool),
+		expireCnt: make(map[string]int),
+	}
+}
+
+func (c *fakeRedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counters[key]++
+	delete(c.deleted, key)
+	return c.counters[key], nil
+}
+
+func (c *fakeRedisClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireCnt[key]++
+	return nil
+}
+
+func (c *fakeRedisClient) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deleted[key] || c.counters[key] == 0 {
+		return "", errNotFound
+	}
+	return strconv.FormatInt(c.counters[key], 10), nil
+}
+
+func (c *fakeRedisClient) Del(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted[key] = true
+	return nil
+}
+
+func (c *fakeRedisClient) expireCount(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expireCnt[key]
+}
+
+// errAlreadyRevoked mirrors how a real etcd client errors when Revoke is
+// called on a lease ID that no longer exists.
+var errAlreadyRevoked = errors.New("fakeEtcdClient: lease already revoked")
+
+// fakeEtcdClient is an in-memory EtcdClient used to exercise EtcdAllocator
+// without a real etcd cluster.
+type fakeEtcdClient struct {
+	mu         sync.Mutex
+	nextLease  int64
+	keys       map[string]int64
+	revoked    map[int64]bool
+	keepAlives map[int64]int
+}
+
+func newFakeEtcdClient() *fakeEtcdClient {
+	return &fakeEtcdClient{
+		keys:       make(map[string]int64),
+		revoked:    make(map[int64]bool),
+		keepAlives: make(map[int64]int),
+	}
+}
+
+func (c *fakeEtcdClient) Grant(ctx context.Context, ttlSeconds int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextLease++
+	return c.nextLease, nil
+}
+
+func (c *fakeEtcdClient) KeepAlive(ctx context.Context, leaseID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keepAlives[leaseID]++
+	return nil
+}
+
+func (c *fakeEtcdClient) PutIfAbsent(ctx context.Context, key, value string, leaseID int64) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.keys[key]; exists {
+		return false, nil
+	}
+	c.keys[key] = leaseID
+	return true, nil
+}
+
+func (c *fakeEtcdClient) Revoke(ctx context.Context, leaseID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.revoked[leaseID] {
+		return errAlreadyRevoked
+	}
+	c.revoked[leaseID] = true
+	for k, v := range c.keys {
+		if v == leaseID {
+			delete(c.keys, k)
+		}
+	}
+	return nil
+}
+
+func (c *fakeEtcdClient) TimeToLive(ctx context.Context, leaseID int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.revoked[leaseID] {
+		return -1, nil
+	}
+	return 10, nil
+}
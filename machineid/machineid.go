@@ -0,0 +1,31 @@
+// Package machineid provides pluggable machine-ID providers for
+// github.com/Snowflake, suitable for Settings.MachineID, beyond the
+// parent package's default of hashing the lower 16 bits of a private IP
+// address. That default breaks down in environments where instances share
+// an IP range or IP-based addressing isn't available at all, which is
+// common in containerized and Kubernetes deployments.
+//
+// RedisAllocator and EtcdAllocator lease a machine ID from a shared store
+// for the lifetime of the process and release the lease on Close.
+// EtcdAllocator claims the lowest unclaimed ID, so a restarted instance can
+// reuse a released one; RedisAllocator draws each ID from an
+// ever-increasing counter and does not reuse released IDs. MACHashMachineID and
+// HostnameOrdinalMachineID are stateless, reading identity out of the
+// environment the process already runs in.
+package machineid
+
+import "errors"
+
+var (
+	// ErrNoMACAddress is returned by MACHashMachineID when no interface
+	// with a hardware address was found.
+	ErrNoMACAddress = errors.New("machineid: no hardware address found")
+
+	// ErrNoOrdinal is returned by HostnameOrdinalMachineID when HOSTNAME
+	// has no trailing "-<n>" ordinal.
+	ErrNoOrdinal = errors.New("machineid: hostname has no trailing ordinal")
+
+	// ErrNoAvailableMachineID is returned by NewEtcdAllocator when every
+	// candidate ID up to maxID is already leased.
+	ErrNoAvailableMachineID = errors.New("machineid: no machine id available")
+)
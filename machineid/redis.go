@@ -0,0 +1,122 @@
+package machineid
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisClient is the minimal subset of a Redis client that RedisAllocator
+// needs. It is satisfied by a thin adapter around most Redis client
+// libraries (e.g. github.com/redis/go-redis/v9).
+type RedisClient interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	Get(ctx context.Context, key string) (string, error)
+	Del(ctx context.Context, key string) error
+}
+
+// RedisAllocator leases a machine ID by INCRing a shared Redis counter key
+// once at startup to obtain a candidate ID, then holding a separate
+// per-ID lease key (counterKey plus the ID) with a TTL that's refreshed by
+// a background heartbeat for as long as the process is alive. If the
+// process dies without calling Close, the lease key's TTL lapses. The
+// counter only ever increases and NewRedisAllocator does not check the
+// lease key before taking an ID, so a lapsed or released ID is not handed
+// out again until the counter passes 65535 and the 16-bit ID wraps around.
+type RedisAllocator struct {
+	client   RedisClient
+	leaseKey string
+	ttl      time.Duration
+	id       uint16
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped bool
+}
+
+// NewRedisAllocator increments counterKey to obtain a machine ID, then
+// claims a lease for that ID with the given TTL and starts a background
+// heartbeat that refreshes the TTL every ttl/2 until Close is called.
+func NewRedisAllocator(client RedisClient, counterKey string, ttl time.Duration) (*RedisAllocator, error) {
+	n, err := client.Incr(context.Background(), counterKey)
+	if err != nil {
+		return nil, err
+	}
+	id := uint16(n)
+	leaseKey := redisLeaseKey(counterKey, id)
+
+	if _, err := client.Incr(context.Background(), leaseKey); err != nil {
+		return nil, err
+	}
+	if err := client.Expire(context.Background(), leaseKey, ttl); err != nil {
+		return nil, err
+	}
+
+	a := &RedisAllocator{
+		client:   client,
+		leaseKey: leaseKey,
+		ttl:      ttl,
+		id:       id,
+		stop:     make(chan struct{}),
+	}
+	go a.heartbeat()
+	return a, nil
+}
+
+// redisLeaseKey returns the per-ID key whose TTL backs the lease on id,
+// namespaced under counterKey so multiple allocators can share a Redis
+// instance.
+func redisLeaseKey(counterKey string, id uint16) string {
+	return counterKey + ":lease:" + strconv.FormatUint(uint64(id), 10)
+}
+
+func (a *RedisAllocator) heartbeat() {
+	ticker := time.NewTicker(a.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = a.client.Expire(context.Background(), a.leaseKey, a.ttl)
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// MachineID returns the leased machine ID. It is suitable for use as
+// Settings.MachineID.
+func (a *RedisAllocator) MachineID() (uint16, error) {
+	return a.id, nil
+}
+
+// CheckMachineID reports whether id is the machine ID currently leased by
+// a, re-reading the lease key from Redis so a lease lost to TTL expiry is
+// actually detected rather than trusting the in-memory id. It checks only
+// that the lease key exists, so it does not detect another process having
+// since claimed the same key. It is suitable for use as
+// Settings.CheckMachineID.
+func (a *RedisAllocator) CheckMachineID(id uint16) bool {
+	if id != a.id {
+		return false
+	}
+
+	_, err := a.client.Get(context.Background(), a.leaseKey)
+	return err == nil
+}
+
+// Close stops the heartbeat and releases the lease immediately, rather
+// than waiting for the TTL to lapse.
+func (a *RedisAllocator) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopped {
+		return nil
+	}
+	a.stopped = true
+	close(a.stop)
+
+	return a.client.Del(context.Background(), a.leaseKey)
+}
@@ -0,0 +1,90 @@
+package machineid
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedisAllocatorLeasesDistinctIDs(t *testing.T) {
+	client := newFakeRedisClient()
+
+	a1, err := NewRedisAllocator(client, "snowflake:machines", time.Second)
+	if err != nil {
+		t.Fatalf("NewRedisAllocator: %v", err)
+	}
+	defer a1.Close()
+
+	a2, err := NewRedisAllocator(client, "snowflake:machines", time.Second)
+	if err != nil {
+		t.Fatalf("NewRedisAllocator: %v", err)
+	}
+	defer a2.Close()
+
+	id1, _ := a1.MachineID()
+	id2, _ := a2.MachineID()
+	if id1 == id2 {
+		t.Fatalf("expected distinct machine ids, got %d and %d", id1, id2)
+	}
+}
+
+func TestRedisAllocatorCheckMachineID(t *testing.T) {
+	client := newFakeRedisClient()
+	a, err := NewRedisAllocator(client, "snowflake:machines", time.Second)
+	if err != nil {
+		t.Fatalf("NewRedisAllocator: %v", err)
+	}
+	defer a.Close()
+
+	id, _ := a.MachineID()
+	if !a.CheckMachineID(id) {
+		t.Error("CheckMachineID = false while lease is held, want true")
+	}
+	if a.CheckMachineID(id + 1) {
+		t.Error("CheckMachineID for a different id = true, want false")
+	}
+
+	// Simulate the lease lapsing, e.g. the process stalled past the TTL.
+	if err := client.Del(context.Background(), a.leaseKey); err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+	if a.CheckMachineID(id) {
+		t.Error("CheckMachineID after lease lapsed = true, want false")
+	}
+}
+
+func TestRedisAllocatorHeartbeatRefreshesLease(t *testing.T) {
+	client := newFakeRedisClient()
+	ttl := 20 * time.Millisecond
+	a, err := NewRedisAllocator(client, "snowflake:machines", ttl)
+	if err != nil {
+		t.Fatalf("NewRedisAllocator: %v", err)
+	}
+	defer a.Close()
+
+	initial := client.expireCount(a.leaseKey)
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for client.expireCount(a.leaseKey) == initial && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := client.expireCount(a.leaseKey); got <= initial {
+		t.Errorf("heartbeat did not refresh the lease: Expire called %d times, want more than %d", got, initial)
+	}
+}
+
+func TestRedisAllocatorCloseIsIdempotent(t *testing.T) {
+	client := newFakeRedisClient()
+	a, err := NewRedisAllocator(client, "snowflake:machines", time.Second)
+	if err != nil {
+		t.Fatalf("NewRedisAllocator: %v", err)
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
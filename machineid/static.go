@@ -0,0 +1,56 @@
+package machineid
+
+import (
+	"hash/fnv"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// MACHashMachineID hashes the hardware addresses of every non-loopback
+// network interface with FNV-1a and masks the result into bitLen bits. It
+// needs no external coordination, unlike RedisAllocator or EtcdAllocator,
+// but collides whenever two instances share the same set of MAC addresses
+// (e.g. macvlan or SR-IOV setups that clone addresses).
+func MACHashMachineID(bitLen uint8) (uint16, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return 0, err
+	}
+
+	h := fnv.New32a()
+	found := false
+	for _, iface := range ifaces {
+		if len(iface.HardwareAddr) == 0 {
+			continue
+		}
+		found = true
+		h.Write(iface.HardwareAddr)
+	}
+	if !found {
+		return 0, ErrNoMACAddress
+	}
+
+	mask := uint32(1<<bitLen - 1)
+	return uint16(h.Sum32() & mask), nil
+}
+
+// hostnameOrdinal matches the trailing "-<n>" ordinal that Kubernetes
+// appends to StatefulSet pod names, e.g. "my-app-7".
+var hostnameOrdinal = regexp.MustCompile(`-(\d+)$`)
+
+// HostnameOrdinalMachineID parses the trailing ordinal off the HOSTNAME
+// environment variable, as set by a Kubernetes StatefulSet pod name.
+func HostnameOrdinalMachineID() (uint16, error) {
+	m := hostnameOrdinal.FindStringSubmatch(os.Getenv("HOSTNAME"))
+	if m == nil {
+		return 0, ErrNoOrdinal
+	}
+
+	n, err := strconv.ParseUint(m[1], 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(n), nil
+}
@@ -0,0 +1,66 @@
+package machineid
+
+import "testing"
+
+func TestHostnameOrdinalMachineID(t *testing.T) {
+	cases := []struct {
+		name     string
+		hostname string
+		want     uint16
+		wantErr  error
+	}{
+		{name: "statefulset pod name", hostname: "my-app-7", want: 7},
+		{name: "multi-digit ordinal", hostname: "my-app-123", want: 123},
+		{name: "no ordinal", hostname: "my-app", wantErr: ErrNoOrdinal},
+		{name: "empty", hostname: "", wantErr: ErrNoOrdinal},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("HOSTNAME", c.hostname)
+
+			got, err := HostnameOrdinalMachineID()
+			if err != c.wantErr {
+				t.Fatalf("HostnameOrdinalMachineID() error = %v, want %v", err, c.wantErr)
+			}
+			if err == nil && got != c.want {
+				t.Errorf("HostnameOrdinalMachineID() = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMACHashMachineIDMasksToBitLen(t *testing.T) {
+	for _, bitLen := range []uint8{4, 8, 11, 16} {
+		id, err := MACHashMachineID(bitLen)
+		if err == ErrNoMACAddress {
+			t.Skip("no hardware address available on this host")
+		}
+		if err != nil {
+			t.Fatalf("MACHashMachineID(%d): %v", bitLen, err)
+		}
+
+		if max := uint16(1<<bitLen - 1); id > max {
+			t.Errorf("MACHashMachineID(%d) = %d, want <= %d", bitLen, id, max)
+		}
+	}
+}
+
+func TestMACHashMachineIDIsDeterministic(t *testing.T) {
+	first, err := MACHashMachineID(16)
+	if err == ErrNoMACAddress {
+		t.Skip("no hardware address available on this host")
+	}
+	if err != nil {
+		t.Fatalf("MACHashMachineID: %v", err)
+	}
+
+	second, err := MACHashMachineID(16)
+	if err != nil {
+		t.Fatalf("MACHashMachineID: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("MACHashMachineID is not deterministic across calls: %d != %d", first, second)
+	}
+}
@@ -0,0 +1,109 @@
+package Snowflake
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// AtomicSnowflake is a lock-free alternative to Snowflake for callers whose
+// QPS makes the mutex in Snowflake.NextID a contention point. It packs the
+// elapsed time and sequence number into a single uint64 state word and
+// advances it with a compare-and-swap retry loop instead of a lock.
+//
+// It accepts the same Settings as Snowflake, including Layout, MachineID,
+// DataCenterID and CheckMachineID. It does not support ClockRollbackPolicy;
+// callers that need clock rollback handling should use Snowflake instead.
+type AtomicSnowflake struct {
+	// state packs the in-flight elapsed time into the high bits and the
+	// sequence number into the low seqBits bits. It is the only field
+	// updated after construction, so it is kept first for alignment.
+	state uint64
+
+	layout Layout
+
+	seqBits           uint8
+	dataCenterIDShift uint8
+	sequenceShift     uint8
+	timeShift         uint8
+	maskSequence      uint64
+	maskDataCenterID  uint64
+	maskNodeID        uint64
+
+	startTime    int64
+	dataCenterID uint16
+	nodeID       uint16
+}
+
+// NewAtomicSnowflake returns a new AtomicSnowflake configured with the given
+// Settings. It returns an error in the same cases as New.
+func NewAtomicSnowflake(st Settings) (*AtomicSnowflake, error) {
+	r, err := resolveSettings(st)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := new(AtomicSnowflake)
+	sf.layout = r.layout
+	sf.seqBits = r.layout.BitLenSequence
+	sf.dataCenterIDShift = r.dataCenterIDShift
+	sf.sequenceShift = r.sequenceShift
+	sf.timeShift = r.timeShift
+	sf.maskSequence = 1<<r.layout.BitLenSequence - 1
+	sf.maskDataCenterID = 1<<r.layout.BitLenDataCenterID - 1
+	sf.maskNodeID = 1<<r.layout.BitLenNodeID - 1
+	sf.startTime = r.startTime
+	sf.dataCenterID = r.dataCenterID
+	sf.nodeID = r.nodeID
+
+	// Start with sequence at its max value, same as Snowflake, so the first
+	// NextID call always lands on the clock-advance branch below and resets
+	// the sequence to 0.
+	sf.state = sf.maskSequence
+
+	return sf, nil
+}
+
+// NextID generates a next unique ID without taking a lock.
+// After the Snowflake time overflows, NextID returns an error.
+func (sf *AtomicSnowflake) NextID() (uint64, error) {
+	for {
+		old := atomic.LoadUint64(&sf.state)
+		oldElapsed := int64(old >> sf.seqBits)
+		oldSeq := old & sf.maskSequence
+
+		current := currentElapsedTime(sf.startTime)
+
+		newElapsed := oldElapsed
+		newSeq := oldSeq
+		overflowed := false
+
+		if oldElapsed < current {
+			newElapsed = current
+			newSeq = 0
+		} else {
+			newSeq = (oldSeq + 1) & sf.maskSequence
+			if newSeq == 0 {
+				newElapsed = oldElapsed + 1
+				overflowed = true
+			}
+		}
+
+		if newElapsed >= 1<<sf.layout.BitLenTime {
+			return 0, ErrOverTimeLimit
+		}
+
+		newState := uint64(newElapsed)<<sf.seqBits | newSeq
+		if !atomic.CompareAndSwapUint64(&sf.state, old, newState) {
+			continue
+		}
+
+		if overflowed {
+			time.Sleep(sleepTime(newElapsed - current))
+		}
+
+		return uint64(newElapsed)<<sf.timeShift |
+			newSeq<<sf.sequenceShift |
+			(uint64(sf.dataCenterID)&sf.maskDataCenterID)<<sf.dataCenterIDShift |
+			(uint64(sf.nodeID) & sf.maskNodeID), nil
+	}
+}
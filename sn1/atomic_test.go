@@ -0,0 +1,42 @@
+package Snowflake
+
+import "testing"
+
+func newBenchSettings() Settings {
+	return Settings{MachineID: func() (uint16, error) { return 1, nil }}
+}
+
+// BenchmarkSnowflakeNextID and BenchmarkAtomicSnowflakeNextID are meant to
+// be compared under contention, e.g. `go test -bench . -cpu 8`: the mutex
+// in Snowflake.NextID serializes all callers, while AtomicSnowflake's CAS
+// retry loop lets independent goroutines make progress concurrently.
+
+func BenchmarkSnowflakeNextID(b *testing.B) {
+	sf, err := New(newBenchSettings())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := sf.NextID(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkAtomicSnowflakeNextID(b *testing.B) {
+	sf, err := NewAtomicSnowflake(newBenchSettings())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := sf.NextID(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package Snowflake
+
+import "time"
+
+// NextIDs generates up to n unique IDs, acquiring the mutex once for the
+// whole batch instead of once per ID as n calls to NextID would. It is
+// meant for bulk-insert workloads that need many IDs at once.
+//
+// If the Snowflake time overflows partway through, NextIDs returns the IDs
+// generated so far along with ErrOverTimeLimit.
+func (sf *Snowflake) NextIDs(n int) ([]uint64, error) {
+	buf := make([]uint64, n)
+	filled, err := sf.nextIDsInto(buf)
+	return buf[:filled], err
+}
+
+// NextIDsInto fills buf with unique IDs, one mutex acquisition for the
+// whole batch, and returns the number of IDs written. Unlike NextIDs, it
+// makes no allocation, for callers that already have a reusable buffer in
+// a hot path.
+//
+// If the Snowflake time overflows partway through, NextIDsInto returns the
+// count filled so far along with ErrOverTimeLimit.
+func (sf *Snowflake) NextIDsInto(buf []uint64) (int, error) {
+	return sf.nextIDsInto(buf)
+}
+
+func (sf *Snowflake) nextIDsInto(buf []uint64) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+
+	maskSequence := uint16(1<<sf.layout.BitLenSequence - 1)
+
+	sf.mutex.Lock()
+	defer sf.mutex.Unlock()
+
+	filled := 0
+	for filled < len(buf) {
+		current := currentElapsedTime(sf.startTime)
+		if current < sf.elapsedTime {
+			var err error
+			current, err = sf.handleClockRollback(current)
+			if err != nil {
+				return filled, err
+			}
+		}
+
+		if sf.elapsedTime < current {
+			sf.elapsedTime = current
+			sf.sequence = 0
+		} else { // sf.elapsedTime >= current
+			sf.sequence = (sf.sequence + 1) & maskSequence
+			if sf.sequence == 0 {
+				sf.elapsedTime++
+				overtime := sf.elapsedTime - current
+				time.Sleep(sleepTime(overtime))
+			}
+		}
+
+		// sf.sequence is now the first unused slot of the current tick.
+		// Drain every remaining slot here, without calling
+		// currentElapsedTime again, before looping back around to check
+		// the wall clock -- this is what makes a batch cheaper than len(buf)
+		// separate NextID calls under contention: one time.Now() and one
+		// chance to sleep per tick, not per ID.
+		for {
+			id, err := sf.toID()
+			if err != nil {
+				return filled, err
+			}
+			buf[filled] = id
+			filled++
+
+			if filled == len(buf) || sf.sequence == maskSequence {
+				break
+			}
+			sf.sequence++
+		}
+	}
+
+	return filled, nil
+}
@@ -0,0 +1,96 @@
+package Snowflake
+
+import "testing"
+
+// newBatchBenchSettings widens the sequence field well past what any of
+// these benchmarks issue in a single 10msec tick, so the benchmarks
+// measure per-ID locking and syscall overhead rather than being swamped by
+// the sequence-overflow sleep every default 256-ID tick forces regardless
+// of batching.
+func newBatchBenchSettings() Settings {
+	return Settings{
+		Layout: Layout{
+			BitLenTime:     39,
+			BitLenSequence: 20,
+			BitLenNodeID:   4,
+		},
+		MachineID: func() (uint16, error) { return 1, nil },
+	}
+}
+
+// BenchmarkNextIDSequential and BenchmarkNextIDsBatch run with a single
+// goroutine, so they mostly measure per-ID overhead rather than the mutex
+// contention NextIDsInto is meant to reduce. BenchmarkNextIDParallel and
+// BenchmarkNextIDsBatchParallel use b.RunParallel, like atomic_test.go
+// does for AtomicSnowflake, to exercise that contention: many goroutines
+// hammering one Snowflake should see NextIDsInto take the mutex far less
+// often per ID generated than NextID.
+
+func BenchmarkNextIDSequential(b *testing.B) {
+	sf, err := New(newBatchBenchSettings())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := sf.NextID(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNextIDsBatch(b *testing.B) {
+	sf, err := New(newBatchBenchSettings())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	const batchSize = 100
+	buf := make([]uint64, batchSize)
+
+	for i := 0; i < b.N; i += batchSize {
+		n := batchSize
+		if i+n > b.N {
+			n = b.N - i
+		}
+		if _, err := sf.NextIDsInto(buf[:n]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNextIDParallel(b *testing.B) {
+	sf, err := New(newBatchBenchSettings())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := sf.NextID(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkNextIDsBatchParallel reports ns/op per batch of batchSize IDs,
+// not per ID -- divide by batchSize to compare against
+// BenchmarkNextIDParallel's ns/op.
+func BenchmarkNextIDsBatchParallel(b *testing.B) {
+	sf, err := New(newBatchBenchSettings())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	const batchSize = 50
+
+	b.RunParallel(func(pb *testing.PB) {
+		buf := make([]uint64, batchSize)
+		for pb.Next() {
+			if _, err := sf.NextIDsInto(buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package Snowflake
+
+import "time"
+
+// ClockRollbackPolicy controls how NextID reacts when the wall clock is
+// observed to be behind the elapsed time of the last issued ID -- for
+// example after an NTP step backwards, or a VM resuming from a suspended
+// snapshot.
+type ClockRollbackPolicy int
+
+const (
+	// RollbackError makes NextID return ErrClockRollback immediately. This
+	// is the default, since it never risks reusing or skipping time.
+	RollbackError ClockRollbackPolicy = iota
+
+	// RollbackWait blocks the caller until time.Now() catches up to the
+	// elapsed time of the last issued ID, up to Settings.MaxRollbackWait.
+	// If the wait exceeds that duration, NextID returns ErrClockRollback.
+	RollbackWait
+
+	// RollbackBorrowTime keeps issuing IDs at the last issued elapsed time
+	// instead of the (rolled-back) wall clock, relying on the same
+	// sequence-overflow sleep that NextID already uses to pace itself back
+	// down to real time once sequence numbers for that tick are exhausted.
+	RollbackBorrowTime
+)
+
+// defaultMaxRollbackWait bounds RollbackWait when Settings.MaxRollbackWait
+// is not set.
+const defaultMaxRollbackWait = 1 * time.Second
+
+// handleClockRollback is called with sf.mutex held whenever current, the
+// wall-clock elapsed time, is behind sf.elapsedTime, the elapsed time of the
+// last issued ID. It returns the elapsed time NextID should proceed with.
+func (sf *Snowflake) handleClockRollback(current int64) (int64, error) {
+	switch sf.clockRollbackPolicy {
+	case RollbackWait:
+		maxWait := sf.maxRollbackWait
+		if maxWait <= 0 {
+			maxWait = defaultMaxRollbackWait
+		}
+		deadline := time.Now().Add(maxWait)
+		for current < sf.elapsedTime {
+			if time.Now().After(deadline) {
+				return 0, ErrClockRollback
+			}
+			// Cap each sleep to the remaining budget so a rollback larger
+			// than maxWait still wakes up in time to observe the deadline,
+			// instead of oversleeping past it in a single step.
+			wait := sleepTime(sf.elapsedTime - current)
+			if remaining := time.Until(deadline); wait > remaining {
+				wait = remaining
+			}
+			time.Sleep(wait)
+			current = currentElapsedTime(sf.startTime)
+		}
+		return current, nil
+
+	case RollbackBorrowTime:
+		return sf.elapsedTime, nil
+
+	default: // RollbackError
+		return 0, ErrClockRollback
+	}
+}
+
+// LastElapsedTime returns the elapsed time of the most recently issued ID.
+// A process can persist this value and, on restart, refuse to issue new IDs
+// until wall clock time exceeds it, guarding against rollback across restarts.
+func (sf *Snowflake) LastElapsedTime() time.Duration {
+	sf.mutex.Lock()
+	defer sf.mutex.Unlock()
+	return time.Duration(sf.elapsedTime * SnowflakeTimeUnit)
+}
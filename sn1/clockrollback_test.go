@@ -0,0 +1,114 @@
+package Snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockRollbackError(t *testing.T) {
+	sf, err := New(Settings{MachineID: func() (uint16, error) { return 1, nil }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := sf.NextID(); err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	// Simulate the wall clock rolling back by pushing elapsedTime far ahead
+	// of where currentElapsedTime will land.
+	sf.mutex.Lock()
+	sf.elapsedTime += 1000
+	sf.mutex.Unlock()
+
+	if _, err := sf.NextID(); err != ErrClockRollback {
+		t.Errorf("NextID after rollback: got %v, want ErrClockRollback", err)
+	}
+}
+
+func TestClockRollbackWaitRecovers(t *testing.T) {
+	sf, err := New(Settings{
+		MachineID:           func() (uint16, error) { return 1, nil },
+		ClockRollbackPolicy: RollbackWait,
+		MaxRollbackWait:     1 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := sf.NextID(); err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	// Push elapsedTime 2 ticks (20msec) ahead of wall clock; RollbackWait
+	// should block until the clock catches up rather than failing.
+	sf.mutex.Lock()
+	sf.elapsedTime += 2
+	sf.mutex.Unlock()
+
+	start := time.Now()
+	if _, err := sf.NextID(); err != nil {
+		t.Fatalf("NextID under RollbackWait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("NextID returned after %v, expected to block for the clock to catch up", elapsed)
+	}
+}
+
+func TestClockRollbackWaitTimesOut(t *testing.T) {
+	sf, err := New(Settings{
+		MachineID:           func() (uint16, error) { return 1, nil },
+		ClockRollbackPolicy: RollbackWait,
+		MaxRollbackWait:     10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := sf.NextID(); err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	// Push elapsedTime far enough ahead that the clock can never catch up
+	// within MaxRollbackWait.
+	sf.mutex.Lock()
+	sf.elapsedTime += 1000
+	sf.mutex.Unlock()
+
+	if _, err := sf.NextID(); err != ErrClockRollback {
+		t.Errorf("NextID under exhausted RollbackWait: got %v, want ErrClockRollback", err)
+	}
+}
+
+func TestClockRollbackBorrowTimeIncreasesMonotonically(t *testing.T) {
+	sf, err := New(Settings{
+		MachineID:           func() (uint16, error) { return 1, nil },
+		ClockRollbackPolicy: RollbackBorrowTime,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	prev, err := sf.NextID()
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	// Push elapsedTime ahead of wall clock; RollbackBorrowTime should keep
+	// issuing IDs from the borrowed (ahead-of-wall-clock) elapsed time
+	// instead of erroring or blocking.
+	sf.mutex.Lock()
+	sf.elapsedTime += 5
+	sf.mutex.Unlock()
+
+	for i := 0; i < 5; i++ {
+		id, err := sf.NextID()
+		if err != nil {
+			t.Fatalf("NextID under RollbackBorrowTime: %v", err)
+		}
+		if id <= prev {
+			t.Fatalf("NextID returned non-increasing id: %d <= %d", id, prev)
+		}
+		prev = id
+	}
+}
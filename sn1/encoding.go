@@ -0,0 +1,127 @@
+package Snowflake
+
+import "strconv"
+
+// NextIDString generates a next unique ID and returns its Base58 string
+// representation, for embedding in URLs and logs where a raw uint64 is
+// awkward to pass around.
+func (sf *Snowflake) NextIDString() (string, error) {
+	id, err := sf.NextID()
+	if err != nil {
+		return "", err
+	}
+	return EncodeBase58(id), nil
+}
+
+// base58Alphabet is the Bitcoin Base58 alphabet: digits and letters with the
+// visually ambiguous 0, O, I and l removed.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base32Alphabet is the Crockford Base32 alphabet: digits and letters with
+// the visually ambiguous I, L, O and U removed.
+const base32Alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// base32Len is the fixed width of a Crockford Base32 encoded Snowflake ID:
+// ceil(64/5) 5-bit groups.
+const base32Len = 13
+
+// EncodeBase58 encodes id using the Bitcoin Base58 alphabet.
+func EncodeBase58(id uint64) string {
+	if id == 0 {
+		return string(base58Alphabet[0])
+	}
+
+	var buf [11]byte // ceil(64 / log2(58)) digits, the most a uint64 ever needs
+	i := len(buf)
+	for id > 0 {
+		i--
+		buf[i] = base58Alphabet[id%58]
+		id /= 58
+	}
+	return string(buf[i:])
+}
+
+// ParseBase58 decodes a string produced by EncodeBase58.
+func ParseBase58(s string) (uint64, error) {
+	if s == "" {
+		return 0, ErrInvalidEncoding
+	}
+
+	var id uint64
+	for i := 0; i < len(s); i++ {
+		v := indexByte(base58Alphabet, s[i])
+		if v < 0 {
+			return 0, ErrInvalidEncoding
+		}
+		id = id*58 + uint64(v)
+	}
+	return id, nil
+}
+
+// EncodeBase32 encodes id as a fixed-width, unpadded Crockford Base32
+// string.
+func EncodeBase32(id uint64) string {
+	var buf [base32Len]byte
+	for i := base32Len - 1; i >= 0; i-- {
+		buf[i] = base32Alphabet[id&0x1f]
+		id >>= 5
+	}
+	return string(buf[:])
+}
+
+// ParseBase32 decodes a string produced by EncodeBase32. Parsing is
+// case-insensitive, matching the Crockford Base32 spec.
+func ParseBase32(s string) (uint64, error) {
+	if len(s) != base32Len {
+		return 0, ErrInvalidEncoding
+	}
+
+	var id uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		v := indexByte(base32Alphabet, c)
+		if v < 0 {
+			return 0, ErrInvalidEncoding
+		}
+		id = id<<5 | uint64(v)
+	}
+	return id, nil
+}
+
+// indexByte returns the index of c in alphabet, or -1 if c is not present.
+func indexByte(alphabet string, c byte) int {
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// ID is a Snowflake ID that marshals to and from JSON as a decimal string
+// rather than a JSON number, so that values above 2^53 survive round trips
+// through JavaScript clients, whose numbers are IEEE 754 float64s.
+type ID uint64
+
+// MarshalJSON implements json.Marshaler.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + strconv.FormatUint(uint64(id), 10) + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*id = ID(v)
+	return nil
+}
@@ -0,0 +1,84 @@
+package Snowflake
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeBase58(t *testing.T) {
+	ids := []uint64{0, 1, 57, 58, 1 << 53, 1<<63 - 1, ^uint64(0)}
+	for _, id := range ids {
+		s := EncodeBase58(id)
+		got, err := ParseBase58(s)
+		if err != nil {
+			t.Fatalf("ParseBase58(%q) error: %v", s, err)
+		}
+		if got != id {
+			t.Errorf("round trip %d -> %q -> %d", id, s, got)
+		}
+	}
+}
+
+func TestParseBase58Invalid(t *testing.T) {
+	for _, s := range []string{"", "0OIl", "!!!"} {
+		if _, err := ParseBase58(s); err == nil {
+			t.Errorf("ParseBase58(%q): expected error, got nil", s)
+		}
+	}
+}
+
+func TestEncodeDecodeBase32(t *testing.T) {
+	ids := []uint64{0, 1, 31, 32, 1 << 53, 1<<63 - 1, ^uint64(0)}
+	for _, id := range ids {
+		s := EncodeBase32(id)
+		if len(s) != base32Len {
+			t.Errorf("EncodeBase32(%d) = %q, want length %d", id, s, base32Len)
+		}
+
+		got, err := ParseBase32(s)
+		if err != nil {
+			t.Fatalf("ParseBase32(%q) error: %v", s, err)
+		}
+		if got != id {
+			t.Errorf("round trip %d -> %q -> %d", id, s, got)
+		}
+
+		if got, err := ParseBase32(strings.ToLower(s)); err != nil || got != id {
+			t.Errorf("ParseBase32(%q) (lowercase) = %d, %v, want %d, nil", strings.ToLower(s), got, err, id)
+		}
+	}
+}
+
+func TestParseBase32Invalid(t *testing.T) {
+	for _, s := range []string{"tooshort", "!IL0U0000000"} {
+		if _, err := ParseBase32(s); err == nil {
+			t.Errorf("ParseBase32(%q): expected error, got nil", s)
+		}
+	}
+}
+
+func TestIDJSONRoundTrip(t *testing.T) {
+	// 2^53 is the largest integer a JavaScript float64 represents exactly;
+	// IDs above it are the motivating case for ID's string encoding.
+	ids := []ID{0, 1, 1<<53 - 1, 1 << 53, 1<<53 + 1, ID(^uint64(0))}
+	for _, id := range ids {
+		data, err := json.Marshal(id)
+		if err != nil {
+			t.Fatalf("Marshal(%d) error: %v", id, err)
+		}
+
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			t.Fatalf("Marshal(%d) = %s, not a JSON string: %v", id, data, err)
+		}
+
+		var got ID
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error: %v", data, err)
+		}
+		if got != id {
+			t.Errorf("round trip %d -> %s -> %d", id, data, got)
+		}
+	}
+}
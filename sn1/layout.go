@@ -0,0 +1,48 @@
+package Snowflake
+
+// Layout describes how the 63 usable bits of a Snowflake ID (the sign bit
+// is always left zero) are split between a timestamp, a sequence number,
+// and a two-level machine identifier. The four fields must sum to 63.
+//
+// Splitting the machine-identifying bits into BitLenDataCenterID and
+// BitLenNodeID lets a deployment address a data center (or region, or
+// rack) independently of the node running inside it. Common layouts
+// include 5+9, 3+16, and 5+5, mirroring several other Snowflake
+// implementations. Leave BitLenDataCenterID at 0 to keep the classic
+// flat machine ID, or widen BitLenTime at the expense of BitLenSequence
+// to extend the ~174 year lifetime of the default layout.
+type Layout struct {
+	BitLenTime         uint8
+	BitLenSequence     uint8
+	BitLenDataCenterID uint8
+	BitLenNodeID       uint8
+}
+
+// These constants describe the bit allocation of the default Layout, used
+// when Settings.Layout is the zero value.
+const (
+	defaultBitLenTime     = 39
+	defaultBitLenSequence = 8
+	defaultBitLenNodeID   = 63 - defaultBitLenTime - defaultBitLenSequence
+)
+
+// defaultLayout reproduces the original, non-hierarchical bit allocation:
+// all machine-identifying bits go to the node ID and none to the data
+// center ID.
+func defaultLayout() Layout {
+	return Layout{
+		BitLenTime:     defaultBitLenTime,
+		BitLenSequence: defaultBitLenSequence,
+		BitLenNodeID:   defaultBitLenNodeID,
+	}
+}
+
+// validate reports whether the layout's four fields sum to the 63 bits
+// available in a Snowflake ID.
+func (l Layout) validate() error {
+	total := int(l.BitLenTime) + int(l.BitLenSequence) + int(l.BitLenDataCenterID) + int(l.BitLenNodeID)
+	if total != 63 {
+		return ErrInvalidBitLen
+	}
+	return nil
+}
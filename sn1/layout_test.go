@@ -0,0 +1,119 @@
+package Snowflake
+
+import "testing"
+
+func TestDefaultLayoutMatchesFlatMachineID(t *testing.T) {
+	sf, err := New(Settings{MachineID: func() (uint16, error) { return 0x1234, nil }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	id, err := sf.NextID()
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	if got := sf.DataCenterID(id); got != 0 {
+		t.Errorf("DataCenterID = %d, want 0 for the default (non-hierarchical) layout", got)
+	}
+	if got := sf.MachineID(id); got != 0x1234 {
+		t.Errorf("MachineID = %d, want 0x1234", got)
+	}
+
+	d := sf.Decompose(id)
+	if d["data-center-id"] != 0 {
+		t.Errorf(`Decompose()["data-center-id"] = %d, want 0`, d["data-center-id"])
+	}
+	if d["machine-id"] != 0x1234 {
+		t.Errorf(`Decompose()["machine-id"] = %d, want 0x1234`, d["machine-id"])
+	}
+}
+
+func TestHierarchicalLayoutRoundTrip(t *testing.T) {
+	cases := []struct {
+		name   string
+		layout Layout
+		dc     uint16
+		node   uint16
+	}{
+		{
+			name:   "5+11",
+			layout: Layout{BitLenTime: 39, BitLenSequence: 8, BitLenDataCenterID: 5, BitLenNodeID: 11},
+			dc:     17,   // fits in 5 bits (max 31)
+			node:   1000, // fits in 11 bits (max 2047)
+		},
+		{
+			name:   "3+16",
+			layout: Layout{BitLenTime: 36, BitLenSequence: 8, BitLenDataCenterID: 3, BitLenNodeID: 16},
+			dc:     5,     // fits in 3 bits (max 7)
+			node:   60000, // fits in 16 bits
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sf, err := New(Settings{
+				Layout:       c.layout,
+				MachineID:    func() (uint16, error) { return c.node, nil },
+				DataCenterID: func() (uint16, error) { return c.dc, nil },
+			})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			id, err := sf.NextID()
+			if err != nil {
+				t.Fatalf("NextID: %v", err)
+			}
+
+			if got := sf.DataCenterID(id); got != uint64(c.dc) {
+				t.Errorf("DataCenterID = %d, want %d", got, c.dc)
+			}
+			if got := sf.MachineID(id); got != uint64(c.node) {
+				t.Errorf("MachineID = %d, want %d", got, c.node)
+			}
+
+			d := sf.Decompose(id)
+			if d["data-center-id"] != uint64(c.dc) {
+				t.Errorf(`Decompose()["data-center-id"] = %d, want %d`, d["data-center-id"], c.dc)
+			}
+			if d["machine-id"] != uint64(c.node) {
+				t.Errorf(`Decompose()["machine-id"] = %d, want %d`, d["machine-id"], c.node)
+			}
+		})
+	}
+}
+
+// TestLayoutMasksOversizedMachineID pins the bug fixed by 0670e61: a
+// MachineID/DataCenterID func that returns a value wider than its
+// configured field must be truncated, not allowed to overrun into the
+// adjacent field.
+func TestLayoutMasksOversizedMachineID(t *testing.T) {
+	sf, err := New(Settings{
+		Layout:       Layout{BitLenTime: 39, BitLenSequence: 8, BitLenDataCenterID: 5, BitLenNodeID: 11},
+		MachineID:    func() (uint16, error) { return 0xFFFF, nil },
+		DataCenterID: func() (uint16, error) { return 0xFFFF, nil },
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	id, err := sf.NextID()
+	if err != nil {
+		t.Fatalf("NextID: %v", err)
+	}
+
+	if got, want := sf.MachineID(id), uint64(1<<11-1); got != want {
+		t.Errorf("MachineID = %d, want %d (masked to 11 bits)", got, want)
+	}
+	if got, want := sf.DataCenterID(id), uint64(1<<5-1); got != want {
+		t.Errorf("DataCenterID = %d, want %d (masked to 5 bits)", got, want)
+	}
+}
+
+func TestNewRejectsInvalidLayout(t *testing.T) {
+	_, err := New(Settings{Layout: Layout{BitLenTime: 39, BitLenSequence: 8, BitLenNodeID: 15}}) // sums to 62
+	if err != ErrInvalidBitLen {
+		t.Errorf("New with invalid layout: got %v, want ErrInvalidBitLen", err)
+	}
+}
@@ -0,0 +1,81 @@
+package Snowflake
+
+import "time"
+
+// resolvedSettings is the configuration shared by Snowflake and
+// AtomicSnowflake: the validated Layout, the shift amounts it implies, and
+// the start time / data center id / node id resolved from Settings. New
+// and NewAtomicSnowflake each copy these into their own fields rather than
+// share a struct, since their hot paths (mutex vs CAS) need different
+// layouts.
+type resolvedSettings struct {
+	layout Layout
+
+	dataCenterIDShift uint8
+	sequenceShift     uint8
+	timeShift         uint8
+
+	startTime    int64
+	dataCenterID uint16
+	nodeID       uint16
+}
+
+// resolveSettings validates st and resolves its StartTime, Layout,
+// MachineID, DataCenterID and CheckMachineID into concrete values, doing
+// everything New and NewAtomicSnowflake need in common. It returns an
+// error in the same cases documented on New.
+func resolveSettings(st Settings) (resolvedSettings, error) {
+	if st.StartTime.After(time.Now()) {
+		return resolvedSettings{}, ErrStartTimeAhead
+	}
+
+	layout := st.Layout
+	if (layout == Layout{}) {
+		layout = defaultLayout()
+	}
+	if err := layout.validate(); err != nil {
+		return resolvedSettings{}, err
+	}
+
+	r := resolvedSettings{
+		layout:            layout,
+		dataCenterIDShift: layout.BitLenNodeID,
+		sequenceShift:     layout.BitLenDataCenterID + layout.BitLenNodeID,
+		timeShift:         layout.BitLenSequence + layout.BitLenDataCenterID + layout.BitLenNodeID,
+	}
+
+	if st.StartTime.IsZero() {
+		r.startTime = toSnowflakeTime(time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC))
+	} else {
+		r.startTime = toSnowflakeTime(st.StartTime)
+	}
+
+	var err error
+	if st.MachineID == nil {
+		r.nodeID, err = lower16BitPrivateIP(defaultInterfaceAddrs)
+	} else {
+		r.nodeID, err = st.MachineID()
+	}
+	if err != nil {
+		return resolvedSettings{}, err
+	}
+	// MachineID/DataCenterID funcs are not required to know the configured
+	// Layout, so truncate their return values to the fields they're
+	// assigned to. Without this, a value wider than its field overruns
+	// into the adjacent bits when the ID is assembled.
+	r.nodeID &= uint16(1<<layout.BitLenNodeID - 1)
+
+	if layout.BitLenDataCenterID > 0 && st.DataCenterID != nil {
+		r.dataCenterID, err = st.DataCenterID()
+		if err != nil {
+			return resolvedSettings{}, err
+		}
+		r.dataCenterID &= uint16(1<<layout.BitLenDataCenterID - 1)
+	}
+
+	if st.CheckMachineID != nil && !st.CheckMachineID(r.nodeID) {
+		return resolvedSettings{}, ErrInvalidMachineID
+	}
+
+	return r, nil
+}
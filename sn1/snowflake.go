@@ -1,10 +1,14 @@
 // Package Snowflake implements Snowflake, a distributed unique ID generator inspired by Twitter's Snowflake.
 //
-// A Snowflake ID is composed of
+// By default, a Snowflake ID is composed of
 //
 //	39 bits for time in units of 10 msec
 //	 8 bits for a sequence number
 //	16 bits for a machine id
+//
+// The bit allocation is configurable via Settings.Layout, including
+// splitting the machine id into a hierarchical data center id and node id.
+// See Layout for details.
 package Snowflake
 
 import (
@@ -16,40 +20,68 @@ import (
 	"github.com/Snowflake/types"
 )
 
-// These constants are the bit lengths of Snowflake ID parts.
-const (
-	BitLenTime      = 39                               // bit length of time
-	BitLenSequence  = 8                                // bit length of sequence number
-	BitLenMachineID = 63 - BitLenTime - BitLenSequence // bit length of machine id
-)
-
 // Settings configures Snowflake:
 //
 // StartTime is the time since which the Snowflake time is defined as the elapsed time.
 // If StartTime is 0, the start time of the Snowflake is set to "2014-09-01 00:00:00 +0000 UTC".
 // If StartTime is ahead of the current time, Snowflake is not created.
 //
-// MachineID returns the unique ID of the Snowflake instance.
+// Layout controls how the 63 usable bits of an ID are split between time,
+// sequence, data center id and node id. If Layout is the zero value, the
+// default layout (39/8/0/16, i.e. no data center split) is used. If the
+// four fields of Layout do not sum to 63, Snowflake is not created.
+//
+// MachineID returns the unique node ID of the Snowflake instance.
 // If MachineID returns an error, Snowflake is not created.
 // If MachineID is nil, default MachineID is used.
 // Default MachineID returns the lower 16 bits of the private IP address.
 //
+// DataCenterID returns the data center ID of the Snowflake instance.
+// It is only consulted when Layout.BitLenDataCenterID is greater than 0.
+// If DataCenterID returns an error, Snowflake is not created.
+// If DataCenterID is nil while Layout.BitLenDataCenterID is greater than 0,
+// the data center ID defaults to 0.
+//
 // CheckMachineID validates the uniqueness of the machine ID.
 // If CheckMachineID returns false, Snowflake is not created.
 // If CheckMachineID is nil, no validation is done.
+//
+// ClockRollbackPolicy controls how NextID reacts if the wall clock is ever
+// behind the elapsed time of the last issued ID. The default, RollbackError,
+// returns ErrClockRollback. See ClockRollbackPolicy for the other options.
+//
+// MaxRollbackWait bounds how long NextID may block under RollbackWait.
+// If MaxRollbackWait is 0, defaultMaxRollbackWait is used.
 type Settings struct {
 	StartTime      time.Time
+	Layout         Layout
 	MachineID      func() (uint16, error)
+	DataCenterID   func() (uint16, error)
 	CheckMachineID func(uint16) bool
+
+	ClockRollbackPolicy ClockRollbackPolicy
+	MaxRollbackWait     time.Duration
 }
 
 // Snowflake is a distributed unique ID generator.
 type Snowflake struct {
-	mutex       *sync.Mutex
-	startTime   int64
-	elapsedTime int64
-	sequence    uint16
-	machineID   uint16
+	mutex  *sync.Mutex
+	layout Layout
+
+	// Precomputed left-shift amounts for each ID part, derived from layout.
+	// The node id is always stored in the low bits, so it needs no shift.
+	dataCenterIDShift uint8
+	sequenceShift     uint8
+	timeShift         uint8
+
+	clockRollbackPolicy ClockRollbackPolicy
+	maxRollbackWait     time.Duration
+
+	startTime    int64
+	elapsedTime  int64
+	sequence     uint16
+	dataCenterID uint16
+	nodeID       uint16
 }
 
 var (
@@ -57,6 +89,9 @@ var (
 	ErrNoPrivateAddress = errors.New("no private ip address")
 	ErrOverTimeLimit    = errors.New("over the time limit")
 	ErrInvalidMachineID = errors.New("invalid machine id")
+	ErrInvalidBitLen    = errors.New("bit lengths of time, sequence, data center id and node id must sum to 63")
+	ErrClockRollback    = errors.New("clock rolled back")
+	ErrInvalidEncoding  = errors.New("invalid encoded id")
 )
 
 var defaultInterfaceAddrs = net.InterfaceAddrs
@@ -64,36 +99,28 @@ var defaultInterfaceAddrs = net.InterfaceAddrs
 // New returns a new Snowflake configured with the given Settings.
 // New returns an error in the following cases:
 // - Settings.StartTime is ahead of the current time.
+// - Settings.Layout is not the zero value and its fields do not sum to 63.
 // - Settings.MachineID returns an error.
+// - Settings.DataCenterID returns an error.
 // - Settings.CheckMachineID returns false.
 func New(st Settings) (*Snowflake, error) {
-	if st.StartTime.After(time.Now()) {
-		return nil, ErrStartTimeAhead
-	}
-
-	sf := new(Snowflake)
-	sf.mutex = new(sync.Mutex)
-	sf.sequence = uint16(1<<BitLenSequence - 1)
-
-	if st.StartTime.IsZero() {
-		sf.startTime = toSnowflakeTime(time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC))
-	} else {
-		sf.startTime = toSnowflakeTime(st.StartTime)
-	}
-
-	var err error
-	if st.MachineID == nil {
-		sf.machineID, err = lower16BitPrivateIP(defaultInterfaceAddrs)
-	} else {
-		sf.machineID, err = st.MachineID()
-	}
+	r, err := resolveSettings(st)
 	if err != nil {
 		return nil, err
 	}
 
-	if st.CheckMachineID != nil && !st.CheckMachineID(sf.machineID) {
-		return nil, ErrInvalidMachineID
-	}
+	sf := new(Snowflake)
+	sf.mutex = new(sync.Mutex)
+	sf.layout = r.layout
+	sf.dataCenterIDShift = r.dataCenterIDShift
+	sf.sequenceShift = r.sequenceShift
+	sf.timeShift = r.timeShift
+	sf.sequence = uint16(1<<r.layout.BitLenSequence - 1)
+	sf.clockRollbackPolicy = st.ClockRollbackPolicy
+	sf.maxRollbackWait = st.MaxRollbackWait
+	sf.startTime = r.startTime
+	sf.dataCenterID = r.dataCenterID
+	sf.nodeID = r.nodeID
 
 	return sf, nil
 }
@@ -101,7 +128,9 @@ func New(st Settings) (*Snowflake, error) {
 // NewSnowflake returns a new Snowflake configured with the given Settings.
 // NewSnowflake returns nil in the following cases:
 // - Settings.StartTime is ahead of the current time.
+// - Settings.Layout is not the zero value and its fields do not sum to 63.
 // - Settings.MachineID returns an error.
+// - Settings.DataCenterID returns an error.
 // - Settings.CheckMachineID returns false.
 func NewSnowflake(st Settings) *Snowflake {
 	sf, _ := New(st)
@@ -111,12 +140,20 @@ func NewSnowflake(st Settings) *Snowflake {
 // NextID generates a next unique ID.
 // After the Snowflake time overflows, NextID returns an error.
 func (sf *Snowflake) NextID() (uint64, error) {
-	const maskSequence = uint16(1<<BitLenSequence - 1)
+	maskSequence := uint16(1<<sf.layout.BitLenSequence - 1)
 
 	sf.mutex.Lock()
 	defer sf.mutex.Unlock()
 
 	current := currentElapsedTime(sf.startTime)
+	if current < sf.elapsedTime {
+		var err error
+		current, err = sf.handleClockRollback(current)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	if sf.elapsedTime < current {
 		sf.elapsedTime = current
 		sf.sequence = 0
@@ -148,13 +185,17 @@ func sleepTime(overtime int64) time.Duration {
 }
 
 func (sf *Snowflake) toID() (uint64, error) {
-	if sf.elapsedTime >= 1<<BitLenTime {
+	if sf.elapsedTime >= 1<<sf.layout.BitLenTime {
 		return 0, ErrOverTimeLimit
 	}
 
-	return uint64(sf.elapsedTime)<<(BitLenSequence+BitLenMachineID) |
-		uint64(sf.sequence)<<BitLenMachineID |
-		uint64(sf.machineID), nil
+	maskDataCenterID := uint64(1<<sf.layout.BitLenDataCenterID - 1)
+	maskNodeID := uint64(1<<sf.layout.BitLenNodeID - 1)
+
+	return uint64(sf.elapsedTime)<<sf.timeShift |
+		uint64(sf.sequence)<<sf.sequenceShift |
+		(uint64(sf.dataCenterID)&maskDataCenterID)<<sf.dataCenterIDShift |
+		(uint64(sf.nodeID) & maskNodeID), nil
 }
 
 func privateIPv4(interfaceAddrs types.InterfaceAddrs) (net.IP, error) {
@@ -193,37 +234,42 @@ func lower16BitPrivateIP(interfaceAddrs types.InterfaceAddrs) (uint16, error) {
 }
 
 // ElapsedTime returns the elapsed time when the given Snowflake ID was generated.
-func ElapsedTime(id uint64) time.Duration {
-	return time.Duration(elapsedTime(id) * SnowflakeTimeUnit)
+func (sf *Snowflake) ElapsedTime(id uint64) time.Duration {
+	return time.Duration(sf.elapsedTimePart(id) * SnowflakeTimeUnit)
 }
 
-func elapsedTime(id uint64) uint64 {
-	return id >> (BitLenSequence + BitLenMachineID)
+func (sf *Snowflake) elapsedTimePart(id uint64) uint64 {
+	return id >> sf.timeShift
 }
 
 // SequenceNumber returns the sequence number of a Snowflake ID.
-func SequenceNumber(id uint64) uint64 {
-	const maskSequence = uint64((1<<BitLenSequence - 1) << BitLenMachineID)
-	return id & maskSequence >> BitLenMachineID
+func (sf *Snowflake) SequenceNumber(id uint64) uint64 {
+	maskSequence := uint64(1<<sf.layout.BitLenSequence-1) << sf.sequenceShift
+	return id & maskSequence >> sf.sequenceShift
+}
+
+// DataCenterID returns the data center ID of a Snowflake ID.
+// It is always 0 when the Snowflake was configured without a data center split.
+func (sf *Snowflake) DataCenterID(id uint64) uint64 {
+	maskDataCenterID := uint64(1<<sf.layout.BitLenDataCenterID-1) << sf.dataCenterIDShift
+	return id & maskDataCenterID >> sf.dataCenterIDShift
 }
 
-// MachineID returns the machine ID of a Snowflake ID.
-func MachineID(id uint64) uint64 {
-	const maskMachineID = uint64(1<<BitLenMachineID - 1)
-	return id & maskMachineID
+// MachineID returns the node ID of a Snowflake ID.
+func (sf *Snowflake) MachineID(id uint64) uint64 {
+	maskNodeID := uint64(1<<sf.layout.BitLenNodeID - 1)
+	return id & maskNodeID
 }
 
-// Decompose returns a set of Snowflake ID parts.
-func Decompose(id uint64) map[string]uint64 {
+// Decompose returns a set of Snowflake ID parts, using this Snowflake's layout.
+func (sf *Snowflake) Decompose(id uint64) map[string]uint64 {
 	msb := id >> 63
-	time := elapsedTime(id)
-	sequence := SequenceNumber(id)
-	machineID := MachineID(id)
 	return map[string]uint64{
-		"id":         id,
-		"msb":        msb,
-		"time":       time,
-		"sequence":   sequence,
-		"machine-id": machineID,
+		"id":             id,
+		"msb":            msb,
+		"time":           sf.elapsedTimePart(id),
+		"sequence":       sf.SequenceNumber(id),
+		"data-center-id": sf.DataCenterID(id),
+		"machine-id":     sf.MachineID(id),
 	}
 }